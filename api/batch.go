@@ -0,0 +1,149 @@
+package api
+
+import (
+	"archive/zip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/dkostenko/plantuml"
+)
+
+// handlerRenderBatch splits a multi-diagram PlantUML source on
+// @startuml/@enduml boundaries, renders each block, and returns them
+// either as a JSON array of base64-encoded outputs or, when the
+// client sends "Accept: application/zip", as a streamed ZIP archive.
+// The source can be supplied as a JSON body or as a multipart upload
+// of a ".puml" file.
+func (m *manager) handlerRenderBatch(w http.ResponseWriter, r *http.Request) {
+	desc, format, ok := m.readBatchRequest(w, r)
+	if !ok {
+		return
+	}
+
+	rendered, err := m.client.RenderAll(desc, format)
+	if err != nil {
+		m.sendErr(w, 4, nil)
+		return
+	}
+
+	if r.Header.Get("Accept") == "application/zip" {
+		m.writeBatchZIP(w, rendered, format)
+		return
+	}
+
+	m.writeBatchJSON(w, rendered)
+}
+
+// readBatchRequest extracts the diagram source and output format from
+// a render-batch request.
+func (m *manager) readBatchRequest(w http.ResponseWriter, r *http.Request) (string, plantuml.DiagramFormat, bool) {
+	var data, formatName string
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			m.sendErr(w, 2, nil)
+			return "", 0, false
+		}
+		defer file.Close()
+
+		content, err := ioutil.ReadAll(file)
+		if err != nil {
+			m.sendErr(w, 2, nil)
+			return "", 0, false
+		}
+		data = string(content)
+		formatName = r.FormValue("format")
+	} else {
+		decoder := json.NewDecoder(r.Body)
+		var prms prmsRenderDiagram
+		if err := decoder.Decode(&prms); err != nil {
+			m.sendErr(w, 2, nil)
+			return "", 0, false
+		}
+		data = prms.Data
+		formatName = prms.Format
+	}
+
+	var format plantuml.DiagramFormat
+	switch formatName {
+	case "svg":
+		format = plantuml.DiagramFormatSVG
+	case "png":
+		format = plantuml.DiagramFormatPNG
+	case "txt":
+		format = plantuml.DiagramFormatTXT
+	default:
+		m.sendErr(w, 4, nil)
+		return "", 0, false
+	}
+
+	return data, format, true
+}
+
+// writeBatchJSON sends rendered diagrams as a JSON array of
+// base64-encoded outputs, with any per-diagram syntax errors.
+func (m *manager) writeBatchJSON(w http.ResponseWriter, rendered []plantuml.RenderedDiagram) {
+	items := make([]batchItem, len(rendered))
+	for i, d := range rendered {
+		item := batchItem{Data: base64.StdEncoding.EncodeToString(d.Data)}
+		for _, se := range d.SyntaxErrors {
+			item.SyntaxErrors = append(item.SyntaxErrors, batchSyntaxError{
+				Line:          se.LineNumber,
+				LineWithError: se.LineWithError,
+			})
+		}
+		items[i] = item
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(items)
+}
+
+// batchItem - one entry of the 'handlerRenderBatch' JSON response.
+type batchItem struct {
+	Data         string             `json:"data"`
+	SyntaxErrors []batchSyntaxError `json:"syntax_errors,omitempty"`
+}
+
+// batchSyntaxError - JSON representation of one syntax error found in
+// a batchItem's diagram.
+type batchSyntaxError struct {
+	Line          int64  `json:"line"`
+	LineWithError string `json:"line_with_error"`
+}
+
+// writeBatchZIP streams rendered diagrams as a ZIP archive with
+// entries "diagram-01.<ext>", "diagram-02.<ext>", etc.
+func (m *manager) writeBatchZIP(w http.ResponseWriter, rendered []plantuml.RenderedDiagram, format plantuml.DiagramFormat) {
+	w.Header().Set("Content-Type", "application/zip")
+	w.WriteHeader(http.StatusOK)
+
+	zw := zip.NewWriter(w)
+	defer zw.Close()
+
+	ext := batchFormatExt(format)
+	for i, d := range rendered {
+		entry, err := zw.Create(fmt.Sprintf("diagram-%02d.%s", i+1, ext))
+		if err != nil {
+			continue
+		}
+		entry.Write(d.Data)
+	}
+}
+
+// batchFormatExt returns the file extension used for ZIP entries.
+func batchFormatExt(format plantuml.DiagramFormat) string {
+	switch format {
+	case plantuml.DiagramFormatSVG:
+		return "svg"
+	case plantuml.DiagramFormatPNG:
+		return "png"
+	default:
+		return "txt"
+	}
+}
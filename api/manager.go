@@ -2,13 +2,17 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"strings"
 
 	assetfs "github.com/elazarl/go-bindata-assetfs"
 	"github.com/gorilla/mux"
 
 	"github.com/dkostenko/plantuml"
+	"github.com/dkostenko/plantuml/dsl"
+	perrors "github.com/dkostenko/plantuml/errors"
 )
 
 // manager - an implementation of a manager of requests to HTTP API server.
@@ -16,6 +20,10 @@ type manager struct {
 	// PlantUML client.
 	client plantuml.Manager
 	router *mux.Router
+
+	// debug - when true, error responses include the captured call
+	// stack alongside the digested error.
+	debug bool
 }
 
 // Manager of requests to HTTP API server.
@@ -24,14 +32,23 @@ type Manager interface {
 	Listen(addr string) error
 }
 
-// NewManager returns manager of requests to HTTP API server.
-func NewManager(client plantuml.Manager) Manager {
+// NewManager returns manager of requests to HTTP API server. When
+// debug is true, error responses include the call stack captured at
+// the point the underlying error was created.
+func NewManager(client plantuml.Manager, debug bool) Manager {
 	m := &manager{
 		client: client,
 		router: mux.NewRouter(),
+		debug:  debug,
 	}
 
 	m.router.HandleFunc("/api/render-diagram", m.handlerRenderDiagram).Methods("POST")
+	m.router.HandleFunc("/api/render-diagram/{key}", m.handlerCachedDiagram).Methods("GET")
+	m.router.HandleFunc("/api/render-from-dsl", m.handlerRenderFromDSL).Methods("POST")
+	m.router.HandleFunc("/api/render-batch", m.handlerRenderBatch).Methods("POST")
+	m.router.HandleFunc("/api/permalink/{encoded}", m.handlerPermalink).Methods("GET")
+	m.router.HandleFunc("/api/permalink", m.handlerCreatePermalink).Methods("POST")
+	m.router.HandleFunc("/playground", m.handlerPlaygroundPage).Methods("GET")
 	m.router.Handle("/", http.FileServer(
 		&assetfs.AssetFS{
 			Asset:     Asset,
@@ -77,20 +94,19 @@ func (m *manager) handlerRenderDiagram(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	diagramFile, syntaxErr, err := m.client.Render(prms.Data, format)
+	diagramFile, syntaxErrors, err := m.client.Render(prms.Data, format)
 	if err != nil {
-		if err.(*plantuml.Error).PackageError == plantuml.ErrInvalidDiagramDescription {
-			m.sendErr(w, 3, map[string]interface{}{
-				"syntax_error_line": syntaxErr.LineNumber,
-				"line_with_error":   syntaxErr.LineWithError,
-				"raw":               syntaxErr.RawError,
-			})
+		if perrors.Is(err, perrors.InvalidDescription) {
+			m.sendSyntaxErrors(w, syntaxErrors, err)
 		} else {
 			m.sendErr(w, 4, nil)
 		}
 		return
 	}
 
+	if key, keyErr := plantuml.CacheKey(prms.Data, format); keyErr == nil {
+		w.Header().Set("ETag", fmt.Sprintf("%q", key))
+	}
 	w.WriteHeader(http.StatusOK)
 	w.Write(diagramFile)
 }
@@ -101,6 +117,114 @@ type prmsRenderDiagram struct {
 	Format string `json:"format"`
 }
 
+// handlerCachedDiagram serves a previously rendered diagram directly
+// from the Manager's cache by its content-addressed key, so browsers
+// can hit cached images without resending the diagram description.
+func (m *manager) handlerCachedDiagram(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+
+	data, ok := m.client.CachedDiagram(key)
+	if !ok {
+		m.sendErr(w, 4, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentTypeForKey(key))
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("ETag", fmt.Sprintf("%q", key))
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// contentTypeForKey returns the Content-Type for a cache key's file
+// extension, e.g. "<sha>.svg" -> "image/svg+xml".
+func contentTypeForKey(key string) string {
+	ext := key
+	if i := strings.LastIndexByte(key, '.'); i >= 0 {
+		ext = key[i+1:]
+	}
+
+	switch ext {
+	case "svg":
+		return "image/svg+xml"
+	case "png":
+		return "image/png"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// handlerRenderFromDSL builds a diagram from an ordered list of dsl
+// ops and sends the rendered diagram in the specified format.
+func (m *manager) handlerRenderFromDSL(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	var prms prmsRenderFromDSL
+	err := decoder.Decode(&prms)
+	if err != nil {
+		m.sendErr(w, 2, nil)
+		return
+	}
+
+	var format plantuml.DiagramFormat
+	switch prms.Format {
+	case "svg":
+		format = plantuml.DiagramFormatSVG
+	case "png":
+		format = plantuml.DiagramFormatPNG
+	case "txt":
+		format = plantuml.DiagramFormatTXT
+	default:
+		m.sendErr(w, 4, nil)
+		return
+	}
+
+	d, err := dsl.FromOps(prms.Ops)
+	if err != nil {
+		m.sendErr(w, 2, nil)
+		return
+	}
+
+	diagramFile, syntaxErrors, err := m.client.RenderDiagram(d, format)
+	if err != nil {
+		if perrors.Is(err, perrors.InvalidDescription) {
+			m.sendSyntaxErrors(w, syntaxErrors, err)
+		} else {
+			m.sendErr(w, 4, nil)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(diagramFile)
+}
+
+// prmsRenderFromDSL - body params for 'handlerRenderFromDSL'.
+type prmsRenderFromDSL struct {
+	Ops    []dsl.Op `json:"ops"`
+	Format string   `json:"format"`
+}
+
+// sendSyntaxErrors sends the full list of syntax errors found in a
+// diagram description, plus (in debug mode) the call stack captured
+// when err was created.
+func (m *manager) sendSyntaxErrors(w http.ResponseWriter, syntaxErrors []plantuml.SyntaxError, err error) {
+	errs := make([]map[string]interface{}, len(syntaxErrors))
+	for i, se := range syntaxErrors {
+		errs[i] = map[string]interface{}{
+			"line":            se.LineNumber,
+			"line_with_error": se.LineWithError,
+			"raw":             se.RawError,
+		}
+	}
+
+	data := map[string]interface{}{"syntax_errors": errs}
+	if m.debug {
+		data["stack_trace"] = perrors.StackTrace(err)
+	}
+
+	m.sendErr(w, 3, data)
+}
+
 // sendErr sends to the client a server error in standart wrapper.
 func (m *manager) sendErr(w http.ResponseWriter, errorCode int64, errorData interface{}) {
 	w.WriteHeader(http.StatusInternalServerError)
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/dkostenko/plantuml"
+	perrors "github.com/dkostenko/plantuml/errors"
+)
+
+// handlerPlaygroundPage serves the playground's HTML editor: a
+// textarea for the diagram description and a pane that renders it
+// live via /api/render-diagram.
+func (m *manager) handlerPlaygroundPage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(playgroundHTML))
+}
+
+// handlerPermalink decodes a PlantUML-encoded description embedded in
+// the URL and renders it directly.
+func (m *manager) handlerPermalink(w http.ResponseWriter, r *http.Request) {
+	encoded := mux.Vars(r)["encoded"]
+
+	desc, err := plantuml.DecodePermalink(encoded)
+	if err != nil {
+		m.sendErr(w, 2, nil)
+		return
+	}
+
+	format := plantuml.DiagramFormatSVG
+	switch r.URL.Query().Get("format") {
+	case "", "svg":
+		format = plantuml.DiagramFormatSVG
+	case "png":
+		format = plantuml.DiagramFormatPNG
+	case "txt":
+		format = plantuml.DiagramFormatTXT
+	default:
+		m.sendErr(w, 4, nil)
+		return
+	}
+
+	diagramFile, syntaxErrors, err := m.client.Render(desc, format)
+	if err != nil {
+		if perrors.Is(err, perrors.InvalidDescription) {
+			m.sendSyntaxErrors(w, syntaxErrors, err)
+		} else {
+			m.sendErr(w, 4, nil)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(diagramFile)
+}
+
+// handlerCreatePermalink encodes a diagram description and returns a
+// share link for the playground.
+func (m *manager) handlerCreatePermalink(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	var prms prmsCreatePermalink
+	err := decoder.Decode(&prms)
+	if err != nil {
+		m.sendErr(w, 2, nil)
+		return
+	}
+
+	encoded, err := plantuml.EncodePermalink(prms.Data)
+	if err != nil {
+		m.sendErr(w, 4, nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	enc.Encode(map[string]string{"url": fmt.Sprintf("/playground#%s", encoded)})
+}
+
+// prmsCreatePermalink - body params for 'handlerCreatePermalink'.
+type prmsCreatePermalink struct {
+	Data string `json:"data"`
+}
+
+// playgroundHTML is the playground's single-page editor: a textarea
+// for the diagram description, a pane that renders it on change via
+// /api/render-diagram, and a "Share" button backed by /api/permalink.
+const playgroundHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>PlantUML Playground</title>
+<style>
+body { display: flex; height: 100vh; margin: 0; font-family: sans-serif; }
+textarea { width: 50%; border: none; padding: 1em; font-family: monospace; font-size: 14px; }
+#preview { width: 50%; padding: 1em; overflow: auto; }
+#error { color: #b00; white-space: pre-wrap; }
+#permalink { position: fixed; top: 1em; right: 1em; }
+</style>
+</head>
+<body>
+<textarea id="source" spellcheck="false">@startuml
+Alice -> Bob: Hello
+@enduml</textarea>
+<div id="preview">
+<div id="error"></div>
+<img id="diagram">
+</div>
+<button id="permalink">Share</button>
+<script>
+var source = document.getElementById('source');
+var diagram = document.getElementById('diagram');
+var errorBox = document.getElementById('error');
+var timer;
+
+function render() {
+	fetch('/api/render-diagram', {
+		method: 'POST',
+		headers: {'Content-Type': 'application/json'},
+		body: JSON.stringify({data: source.value, format: 'svg'})
+	}).then(function (resp) {
+		if (resp.ok) {
+			errorBox.textContent = '';
+			return resp.blob().then(function (blob) {
+				diagram.src = URL.createObjectURL(blob);
+			});
+		}
+		return resp.json().then(function (body) {
+			var errs = (body.error_data || {}).syntax_errors || [];
+			errorBox.textContent = errs.map(function (e) {
+				return 'Line ' + e.line + ': ' + e.line_with_error;
+			}).join('\n');
+		});
+	});
+}
+
+source.addEventListener('input', function () {
+	clearTimeout(timer);
+	timer = setTimeout(render, 300);
+});
+
+document.getElementById('permalink').addEventListener('click', function () {
+	fetch('/api/permalink', {
+		method: 'POST',
+		headers: {'Content-Type': 'application/json'},
+		body: JSON.stringify({data: source.value})
+	}).then(function (resp) {
+		return resp.json();
+	}).then(function (body) {
+		window.location = body.url;
+	});
+});
+
+var hash = window.location.hash.slice(1);
+if (hash) {
+	diagram.src = '/api/permalink/' + hash;
+} else {
+	render();
+}
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,136 @@
+package plantuml
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"net/url"
+	"strings"
+
+	perrors "github.com/dkostenko/plantuml/errors"
+)
+
+// plantumlAlphabet is the 64-character alphabet PlantUML uses to
+// text-encode a deflated diagram description into a URL-safe string.
+const plantumlAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz-_"
+
+// plantumlAlphabetIndex maps each plantumlAlphabet character back to
+// its 6-bit value, for decoding.
+var plantumlAlphabetIndex = buildPlantUMLAlphabetIndex()
+
+func buildPlantUMLAlphabetIndex() map[byte]byte {
+	index := make(map[byte]byte, len(plantumlAlphabet))
+	for i := 0; i < len(plantumlAlphabet); i++ {
+		index[plantumlAlphabet[i]] = byte(i)
+	}
+	return index
+}
+
+// EncodedURLBackend renders diagrams by encoding the description with
+// PlantUML's DEFLATE+base64 text-encoding scheme and issuing a single
+// GET against "<server>/<fmt>/<encoded>", avoiding the two-step
+// "/form" + "/txt/<id>" dance the HTTP backend uses.
+type EncodedURLBackend struct {
+	// PlantUML server address.
+	serverAddr string
+}
+
+// NewEncodedURLBackend returns a Backend that renders diagrams via
+// encoded-URL GET requests against plantUMLServerAddr.
+func NewEncodedURLBackend(plantUMLServerAddr string) (*EncodedURLBackend, error) {
+	// Validate plantUMLServerAddr.
+	_, err := url.ParseRequestURI(plantUMLServerAddr)
+	if err != nil {
+		return nil, perrors.Wrap(err, perrors.InvalidAddress, "")
+	}
+
+	return &EncodedURLBackend{serverAddr: plantUMLServerAddr}, nil
+}
+
+// Render returns diagram file in the specified format.
+func (b *EncodedURLBackend) Render(diagramDescription string, format DiagramFormat) ([]byte, []SyntaxError, error) {
+	ext, err := diagramFormatExt(format)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	encoded, err := encodePlantUML(diagramDescription)
+	if err != nil {
+		return nil, nil, perrors.Wrap(err, perrors.Internal, "")
+	}
+
+	link := fmt.Sprintf("%s/%s/%s", b.serverAddr, ext, encoded)
+	diagramFile, hasSyntaxError, err := downloadDiagram(link)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if hasSyntaxError {
+		syntaxErrors := ParseSyntaxErrors(string(diagramFile))
+		if len(syntaxErrors) > 0 {
+			return nil, syntaxErrors, perrors.Wrap(nil, perrors.InvalidDescription, "")
+		}
+	}
+
+	return diagramFile, nil, nil
+}
+
+// encodePlantUML deflates desc and text-encodes it with the PlantUML
+// alphabet, as described at https://plantuml.com/text-encoding.
+func encodePlantUML(desc string) (string, error) {
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write([]byte(desc)); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	return encodePlantUMLBytes(buf.Bytes()), nil
+}
+
+// encodePlantUMLBytes packs data three bytes at a time into four
+// 6-bit characters from plantumlAlphabet.
+func encodePlantUMLBytes(data []byte) string {
+	var b strings.Builder
+	for i := 0; i < len(data); i += 3 {
+		var c1, c2, c3 byte
+		c1 = data[i]
+		if i+1 < len(data) {
+			c2 = data[i+1]
+		}
+		if i+2 < len(data) {
+			c3 = data[i+2]
+		}
+		b.WriteByte(plantumlAlphabet[c1>>2])
+		b.WriteByte(plantumlAlphabet[((c1&0x3)<<4)|(c2>>4)])
+		b.WriteByte(plantumlAlphabet[((c2&0xF)<<2)|(c3>>6)])
+		b.WriteByte(plantumlAlphabet[c3&0x3F])
+	}
+	return b.String()
+}
+
+// decodePlantUMLBytes reverses encodePlantUMLBytes, unpacking four
+// 6-bit characters from plantumlAlphabet back into three bytes at a
+// time.
+func decodePlantUMLBytes(encoded string) ([]byte, error) {
+	var out bytes.Buffer
+	for i := 0; i+4 <= len(encoded); i += 4 {
+		var c [4]byte
+		for j, ch := range []byte(encoded[i : i+4]) {
+			v, ok := plantumlAlphabetIndex[ch]
+			if !ok {
+				return nil, fmt.Errorf("plantuml: invalid character %q in encoded description", ch)
+			}
+			c[j] = v
+		}
+		out.WriteByte((c[0] << 2) | (c[1] >> 4))
+		out.WriteByte(((c[1] & 0xF) << 4) | (c[2] >> 2))
+		out.WriteByte(((c[2] & 0x3) << 6) | c[3])
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,109 @@
+package plantuml
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	perrors "github.com/dkostenko/plantuml/errors"
+)
+
+// httpBackend renders diagrams through an HTTP PlantUML server, using
+// its "/form" + "/<fmt>/<id>" flow.
+type httpBackend struct {
+	// PlantUML server address.
+	serverAddr string
+}
+
+// newHTTPBackend returns a Backend that talks to an HTTP PlantUML
+// server at plantUMLServerAddr.
+func newHTTPBackend(plantUMLServerAddr string) (Backend, error) {
+	// Validate plantUMLServerAddr.
+	_, err := url.ParseRequestURI(plantUMLServerAddr)
+	if err != nil {
+		return nil, perrors.Wrap(err, perrors.InvalidAddress, "")
+	}
+
+	return &httpBackend{serverAddr: plantUMLServerAddr}, nil
+}
+
+// Render returns diagram file in the specified format.
+func (b *httpBackend) Render(diagramDescription string, format DiagramFormat) ([]byte, []SyntaxError, error) {
+	formatURLPart, err := diagramFormatExt(format)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 1. Get rendered diagram ID.
+	link := fmt.Sprintf("%s/form", b.serverAddr)
+	imgID, err := getDiagramID(link, diagramDescription)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 2. Get the diagram as TXT to check an error existence.
+	link = fmt.Sprintf("%s/txt/%s", b.serverAddr, imgID)
+	diagramFile, hasSyntaxError, err := downloadDiagram(link)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 3. Check the error if needed.
+	if hasSyntaxError {
+		syntaxErrors := ParseSyntaxErrors(string(diagramFile))
+		if len(syntaxErrors) > 0 {
+			return nil, syntaxErrors, perrors.Wrap(nil, perrors.InvalidDescription, "")
+		}
+	}
+
+	// 4. Render the diagram in a requred format.
+	if format == DiagramFormatTXT {
+		return diagramFile, nil, nil
+	}
+	link = fmt.Sprintf("%s/%s/%s", b.serverAddr, formatURLPart, imgID)
+	diagramFile, _, err = downloadDiagram(link)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return diagramFile, nil, nil
+}
+
+// getDiagramID returns ID of the rendered diagram.
+func getDiagramID(link, diagramDescription string) (string, error) {
+	resp, err := http.PostForm(link, url.Values{"text": {diagramDescription}})
+	if err != nil {
+		return "", perrors.Wrap(err, perrors.Internal, "")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 && resp.StatusCode != 400 {
+		return "", perrors.Wrap(err, perrors.ServerUnavailable, "")
+	}
+
+	urlParts := strings.Split(resp.Request.URL.String(), "/")
+	imgID := urlParts[len(urlParts)-1]
+	return imgID, nil
+}
+
+// downloadDiagram returns diagram and 'has syntax error' flag.
+func downloadDiagram(link string) ([]byte, bool, error) {
+	resp, err := http.Get(link)
+	if err != nil {
+		return nil, false, perrors.Wrap(err, perrors.Internal, "")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 && resp.StatusCode != 400 {
+		return nil, false, perrors.Wrap(nil, perrors.ServerUnavailable, "")
+	}
+	diagramFile, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, perrors.Wrap(err, perrors.Internal, "")
+	}
+	if resp.StatusCode == 400 {
+		return diagramFile, true, nil
+	}
+	return diagramFile, false, nil
+}
@@ -0,0 +1,56 @@
+package plantuml
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+
+	perrors "github.com/dkostenko/plantuml/errors"
+)
+
+// JarBackend renders diagrams by shelling out to a local PlantUML jar
+// (`java -jar plantuml.jar -pipe`), streaming the description over
+// stdin and reading the rendered bytes from stdout. It is useful for
+// offline or CI usage where an HTTP PlantUML server isn't available.
+type JarBackend struct {
+	// javaBin - path to the java executable.
+	javaBin string
+
+	// jarPath - path to plantuml.jar.
+	jarPath string
+}
+
+// NewJarBackend returns a Backend that renders diagrams with the
+// PlantUML jar at jarPath, invoked through the "java" executable
+// found on PATH.
+func NewJarBackend(jarPath string) *JarBackend {
+	return &JarBackend{javaBin: "java", jarPath: jarPath}
+}
+
+// Render returns diagram file in the specified format.
+func (b *JarBackend) Render(diagramDescription string, format DiagramFormat) ([]byte, []SyntaxError, error) {
+	ext, err := diagramFormatExt(format)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cmd := exec.Command(b.javaBin, "-jar", b.jarPath, "-pipe", "-t"+ext)
+	cmd.Stdin = strings.NewReader(diagramDescription)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, nil, perrors.Wrap(err, perrors.Internal, "")
+	}
+
+	if stderr.Len() > 0 {
+		syntaxErrors := ParseSyntaxErrors(stderr.String())
+		if len(syntaxErrors) > 0 {
+			return nil, syntaxErrors, perrors.Wrap(nil, perrors.InvalidDescription, "")
+		}
+	}
+
+	return stdout.Bytes(), nil, nil
+}
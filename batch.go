@@ -0,0 +1,55 @@
+package plantuml
+
+import (
+	"strings"
+
+	perrors "github.com/dkostenko/plantuml/errors"
+)
+
+// RenderedDiagram is one diagram rendered as part of a RenderAll
+// batch.
+type RenderedDiagram struct {
+	// Data - the rendered diagram file.
+	Data []byte
+
+	// SyntaxErrors - syntax errors found in this diagram, if any.
+	SyntaxErrors []SyntaxError
+}
+
+// RenderAll splits desc on "@startuml"/"@enduml" boundaries and
+// renders each diagram block in order, so a file containing several
+// diagrams can be rendered in one call.
+func (m *manager) RenderAll(desc string, format DiagramFormat) ([]RenderedDiagram, error) {
+	blocks := splitDiagramBlocks(desc)
+	if len(blocks) == 0 {
+		return nil, perrors.Wrap(nil, perrors.InvalidDescription, "diagram description has no @startuml block")
+	}
+
+	rendered := make([]RenderedDiagram, len(blocks))
+	for i, block := range blocks {
+		data, syntaxErrors, err := m.Render(block, format)
+		if err != nil && len(syntaxErrors) == 0 {
+			return nil, err
+		}
+		rendered[i] = RenderedDiagram{Data: data, SyntaxErrors: syntaxErrors}
+	}
+
+	return rendered, nil
+}
+
+// splitDiagramBlocks splits desc into individual "@startuml" ...
+// "@enduml" blocks, in order, dropping anything outside of them.
+func splitDiagramBlocks(desc string) []string {
+	var blocks []string
+	for _, part := range strings.Split(desc, "@startuml")[1:] {
+		if end := strings.Index(part, "@enduml"); end >= 0 {
+			part = part[:end+len("@enduml")]
+		}
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		blocks = append(blocks, "@startuml\n"+part)
+	}
+	return blocks
+}
@@ -0,0 +1,34 @@
+package plantuml
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Cache memoizes Render results, keyed by a content-addressed hash of
+// the diagram description and output format.
+type Cache interface {
+	// Get returns cached diagram data for key, if present.
+	Get(key string) ([]byte, bool)
+
+	// Put stores diagram data for key.
+	Put(key string, data []byte)
+}
+
+// CacheKey returns the content-addressed cache key for a diagram
+// description rendered in the given format: "<sha256(desc|ext)>.<ext>".
+// diagramDescription is trimmed the same way Render trims it, so
+// callers computing a key from raw, untrimmed input (e.g. an ETag
+// computed from a request body) still match the key Render actually
+// stores under.
+func CacheKey(diagramDescription string, format DiagramFormat) (string, error) {
+	ext, err := diagramFormatExt(format)
+	if err != nil {
+		return "", err
+	}
+
+	desc := strings.Trim(diagramDescription, " ")
+	sum := sha256.Sum256([]byte(desc + "|" + ext))
+	return hex.EncodeToString(sum[:]) + "." + ext, nil
+}
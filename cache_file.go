@@ -0,0 +1,52 @@
+package plantuml
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileCache is a filesystem-backed Cache that stores each entry at
+// "<dir>/<sha[:2]>/<sha>.<ext>", where key is "<sha>.<ext>" as
+// returned by CacheKey.
+type FileCache struct {
+	dir string
+}
+
+// NewFileCache returns a FileCache that stores rendered diagrams
+// under dir.
+func NewFileCache(dir string) *FileCache {
+	return &FileCache{dir: dir}
+}
+
+// Get returns cached diagram data for key, if present.
+func (c *FileCache) Get(key string) ([]byte, bool) {
+	data, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// Put stores diagram data for key.
+func (c *FileCache) Put(key string, data []byte) {
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	ioutil.WriteFile(path, data, 0644)
+}
+
+// path returns the on-disk path for key.
+func (c *FileCache) path(key string) string {
+	sha := key
+	if i := strings.IndexByte(key, '.'); i > 0 {
+		sha = key[:i]
+	}
+	shard := sha
+	if len(shard) > 2 {
+		shard = shard[:2]
+	}
+	return filepath.Join(c.dir, shard, key)
+}
@@ -0,0 +1,91 @@
+package plantuml
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// MemoryCache is an in-memory LRU Cache with a configurable byte
+// budget and per-entry TTL.
+type MemoryCache struct {
+	maxBytes int64
+	ttl      time.Duration
+
+	mu       sync.Mutex
+	curBytes int64
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+// memoryCacheEntry - a single MemoryCache entry.
+type memoryCacheEntry struct {
+	key       string
+	data      []byte
+	expiresAt time.Time
+}
+
+// NewMemoryCache returns a MemoryCache that evicts least-recently-used
+// entries once the total cached size exceeds maxBytes, and treats
+// entries older than ttl as missing. A zero ttl disables expiry.
+func NewMemoryCache(maxBytes int64, ttl time.Duration) *MemoryCache {
+	return &MemoryCache{
+		maxBytes: maxBytes,
+		ttl:      ttl,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get returns cached diagram data for key, if present and not
+// expired.
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := el.Value.(*memoryCacheEntry)
+	if c.ttl > 0 && time.Now().After(entry.expiresAt) {
+		c.removeElement(el)
+		return nil, false
+	}
+
+	c.order.MoveToFront(el)
+	return entry.data, true
+}
+
+// Put stores diagram data for key, evicting least-recently-used
+// entries until the cache fits within maxBytes.
+func (c *MemoryCache) Put(key string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+
+	entry := &memoryCacheEntry{key: key, data: data}
+	if c.ttl > 0 {
+		entry.expiresAt = time.Now().Add(c.ttl)
+	}
+
+	el := c.order.PushFront(entry)
+	c.items[key] = el
+	c.curBytes += int64(len(data))
+
+	for c.curBytes > c.maxBytes && c.order.Len() > 0 {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// removeElement removes el from the cache. Caller must hold c.mu.
+func (c *MemoryCache) removeElement(el *list.Element) {
+	entry := el.Value.(*memoryCacheEntry)
+	c.order.Remove(el)
+	delete(c.items, entry.key)
+	c.curBytes -= int64(len(entry.data))
+}
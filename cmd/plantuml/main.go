@@ -1,8 +1,10 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/dkostenko/plantuml"
 	"github.com/dkostenko/plantuml/api"
@@ -17,16 +19,48 @@ func main() {
 	app := cli.App("plantuml", "PlantUML client application.")
 	serverAddr := app.StringOpt("plantuml-server-addr", "", "PlantUML server address.")
 	apiAddr := app.StringOpt("api-addr", "", "PlantUML UI API address.")
+	backend := app.StringOpt("backend", "", `Rendering backend: "jar:<path-to-plantuml.jar>" to render through a local PlantUML jar, or "encoded-url" to render through a single encoded GET against plantuml-server-addr. Defaults to the HTTP server backend.`)
+	cacheDir := app.StringOpt("cache-dir", "", "Directory to cache rendered diagrams in. Unset disables the rendered-diagram cache.")
+	debug := app.BoolOpt("debug", false, "Include the captured call stack in API error responses.")
 
 	// Default action: run server with API and UI for using PlantUML server.
 	app.Action = func() {
-		plantumlManager, err := plantuml.NewManager(*serverAddr)
+		opts, err := backendOptions(*backend, *serverAddr)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		if *cacheDir != "" {
+			opts = append(opts, plantuml.WithCache(plantuml.NewFileCache(*cacheDir)))
+		}
+
+		plantumlManager, err := plantuml.NewManager(*serverAddr, opts...)
 		if err != nil {
 			log.Fatalln(err)
 		}
 
-		apiManager := api.NewManager(plantumlManager)
+		apiManager := api.NewManager(plantumlManager, *debug)
 		log.Fatalln(apiManager.Listen(*apiAddr))
 	}
 	app.Run(os.Args)
 }
+
+// backendOptions returns the plantuml.Option needed to select the
+// rendering backend named by the --backend flag, or nil to keep the
+// default HTTP server backend.
+func backendOptions(backend, serverAddr string) ([]plantuml.Option, error) {
+	switch {
+	case backend == "":
+		return nil, nil
+	case strings.HasPrefix(backend, "jar:"):
+		jarPath := strings.TrimPrefix(backend, "jar:")
+		return []plantuml.Option{plantuml.WithBackend(plantuml.NewJarBackend(jarPath))}, nil
+	case backend == "encoded-url":
+		b, err := plantuml.NewEncodedURLBackend(serverAddr)
+		if err != nil {
+			return nil, err
+		}
+		return []plantuml.Option{plantuml.WithBackend(b)}, nil
+	default:
+		return nil, fmt.Errorf(`plantuml: unknown --backend %q, want "jar:<path>" or "encoded-url"`, backend)
+	}
+}
@@ -0,0 +1,198 @@
+// Package dsl provides a fluent builder for PlantUML diagram sources,
+// so callers can compose diagrams without hand-written string
+// concatenation.
+package dsl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// opKind identifies the kind of operation recorded on a Diagram.
+type opKind int
+
+// Available operation kinds.
+const (
+	opStartUML opKind = iota
+	opEndUML
+	opTitle
+	opSkinparam
+	opParticipant
+	opActor
+	opNote
+	opArrowRight
+	opArrowLeft
+	opBiArrow
+	opGroup
+	opAlt
+	opAltElse
+	opLoop
+	opEnd
+	opRaw
+)
+
+// op - a single recorded operation of a Diagram.
+type op struct {
+	kind opKind
+	args []string
+}
+
+// Diagram is a fluent builder of PlantUML source. Method calls are
+// recorded in the order they happen and turned into PlantUML text by
+// String().
+type Diagram struct {
+	ops []op
+}
+
+// New returns an empty Diagram.
+func New() *Diagram {
+	return &Diagram{}
+}
+
+// StartUML opens the diagram with "@startuml".
+func (d *Diagram) StartUML() *Diagram {
+	return d.append(opStartUML)
+}
+
+// EndUML closes the diagram with "@enduml".
+func (d *Diagram) EndUML() *Diagram {
+	return d.append(opEndUML)
+}
+
+// Title sets the diagram title.
+func (d *Diagram) Title(title string) *Diagram {
+	return d.append(opTitle, title)
+}
+
+// Skinparam appends a "skinparam key value" line.
+func (d *Diagram) Skinparam(key, value string) *Diagram {
+	return d.append(opSkinparam, key, value)
+}
+
+// Participant declares a participant. If alias is empty, the
+// participant is declared without an "as" clause.
+func (d *Diagram) Participant(display, alias string) *Diagram {
+	return d.append(opParticipant, display, alias)
+}
+
+// Actor declares an actor. If alias is empty, the actor is declared
+// without an "as" clause.
+func (d *Diagram) Actor(display, alias string) *Diagram {
+	return d.append(opActor, display, alias)
+}
+
+// Note attaches a note of the given text to a participant, e.g.
+// Note("left", "Bob", "checks the request").
+func (d *Diagram) Note(position, of, text string) *Diagram {
+	return d.append(opNote, position, of, text)
+}
+
+// ArrowRight appends a "from -> to: msg" line.
+func (d *Diagram) ArrowRight(from, to, msg string) *Diagram {
+	return d.append(opArrowRight, from, to, msg)
+}
+
+// ArrowLeft appends a "from <- to: msg" line.
+func (d *Diagram) ArrowLeft(from, to, msg string) *Diagram {
+	return d.append(opArrowLeft, from, to, msg)
+}
+
+// BiArrow appends a "from <-> to: msg" line.
+func (d *Diagram) BiArrow(from, to, msg string) *Diagram {
+	return d.append(opBiArrow, from, to, msg)
+}
+
+// Group opens a "group name" block. Call End to close it.
+func (d *Diagram) Group(name string) *Diagram {
+	return d.append(opGroup, name)
+}
+
+// Alt opens an "alt condition" block. Call End to close it.
+func (d *Diagram) Alt(condition string) *Diagram {
+	return d.append(opAlt, condition)
+}
+
+// AltElse appends an "else condition" branch inside the innermost
+// open Alt block.
+func (d *Diagram) AltElse(condition string) *Diagram {
+	return d.append(opAltElse, condition)
+}
+
+// Loop opens a "loop condition" block. Call End to close it.
+func (d *Diagram) Loop(condition string) *Diagram {
+	return d.append(opLoop, condition)
+}
+
+// End closes the innermost open Group, Alt or Loop block.
+func (d *Diagram) End() *Diagram {
+	return d.append(opEnd)
+}
+
+// Raw appends a line of PlantUML source verbatim. It is an escape
+// hatch for constructs the builder does not cover yet.
+func (d *Diagram) Raw(line string) *Diagram {
+	return d.append(opRaw, line)
+}
+
+// String marshals the recorded operations into PlantUML source.
+func (d *Diagram) String() string {
+	var b strings.Builder
+	for _, o := range d.ops {
+		switch o.kind {
+		case opStartUML:
+			b.WriteString("@startuml\n")
+		case opEndUML:
+			b.WriteString("@enduml\n")
+		case opTitle:
+			fmt.Fprintf(&b, "title %s\n", o.args[0])
+		case opSkinparam:
+			fmt.Fprintf(&b, "skinparam %s %s\n", o.args[0], o.args[1])
+		case opParticipant:
+			b.WriteString(declaration("participant", o.args[0], o.args[1]))
+		case opActor:
+			b.WriteString(declaration("actor", o.args[0], o.args[1]))
+		case opNote:
+			fmt.Fprintf(&b, "note %s of %s: %s\n", o.args[0], o.args[1], o.args[2])
+		case opArrowRight:
+			fmt.Fprintf(&b, "%s -> %s: %s\n", o.args[0], o.args[1], o.args[2])
+		case opArrowLeft:
+			fmt.Fprintf(&b, "%s <- %s: %s\n", o.args[0], o.args[1], o.args[2])
+		case opBiArrow:
+			fmt.Fprintf(&b, "%s <-> %s: %s\n", o.args[0], o.args[1], o.args[2])
+		case opGroup:
+			fmt.Fprintf(&b, "group %s\n", o.args[0])
+		case opAlt:
+			fmt.Fprintf(&b, "alt %s\n", o.args[0])
+		case opAltElse:
+			fmt.Fprintf(&b, "else %s\n", o.args[0])
+		case opLoop:
+			fmt.Fprintf(&b, "loop %s\n", o.args[0])
+		case opEnd:
+			b.WriteString("end\n")
+		case opRaw:
+			b.WriteString(o.args[0])
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// append records an operation and returns d, so calls can be chained.
+func (d *Diagram) append(kind opKind, args ...string) *Diagram {
+	d.ops = append(d.ops, op{kind: kind, args: args})
+	return d
+}
+
+// declaration formats a "keyword display as alias" line, omitting the
+// "as" clause when alias is empty. display is quoted whenever it
+// contains whitespace, since PlantUML requires that to keep it as a
+// single token.
+func declaration(keyword, display, alias string) string {
+	if alias == "" {
+		if strings.ContainsAny(display, " \t") {
+			return fmt.Sprintf("%s %q\n", keyword, display)
+		}
+		return fmt.Sprintf("%s %s\n", keyword, display)
+	}
+	return fmt.Sprintf("%s %q as %s\n", keyword, display, alias)
+}
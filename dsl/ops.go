@@ -0,0 +1,71 @@
+package dsl
+
+import "fmt"
+
+// Op is the JSON representation of a single Diagram operation, as
+// accepted by the render-from-dsl API endpoint.
+type Op struct {
+	// Kind - the name of the Diagram method to call, e.g. "arrow_right".
+	Kind string `json:"kind"`
+
+	// Args - positional arguments for the method, in call order.
+	Args []string `json:"args,omitempty"`
+}
+
+// kindNames maps the JSON op kind to the internal opKind.
+var kindNames = map[string]opKind{
+	"start_uml":   opStartUML,
+	"end_uml":     opEndUML,
+	"title":       opTitle,
+	"skinparam":   opSkinparam,
+	"participant": opParticipant,
+	"actor":       opActor,
+	"note":        opNote,
+	"arrow_right": opArrowRight,
+	"arrow_left":  opArrowLeft,
+	"bi_arrow":    opBiArrow,
+	"group":       opGroup,
+	"alt":         opAlt,
+	"alt_else":    opAltElse,
+	"loop":        opLoop,
+	"end":         opEnd,
+	"raw":         opRaw,
+}
+
+// kindArgCount is the number of positional args String() expects for
+// each op kind. FromOps rejects ops that don't match.
+var kindArgCount = map[opKind]int{
+	opStartUML:    0,
+	opEndUML:      0,
+	opTitle:       1,
+	opSkinparam:   2,
+	opParticipant: 2,
+	opActor:       2,
+	opNote:        3,
+	opArrowRight:  3,
+	opArrowLeft:   3,
+	opBiArrow:     3,
+	opGroup:       1,
+	opAlt:         1,
+	opAltElse:     1,
+	opLoop:        1,
+	opEnd:         0,
+	opRaw:         1,
+}
+
+// FromOps builds a Diagram from an ordered list of JSON ops, in the
+// shape produced by marshalling builder calls on the client side.
+func FromOps(ops []Op) (*Diagram, error) {
+	d := New()
+	for i, o := range ops {
+		kind, ok := kindNames[o.Kind]
+		if !ok {
+			return nil, fmt.Errorf("dsl: unknown op kind %q at index %d", o.Kind, i)
+		}
+		if want := kindArgCount[kind]; len(o.Args) != want {
+			return nil, fmt.Errorf("dsl: op %q at index %d requires %d args, got %d", o.Kind, i, want, len(o.Args))
+		}
+		d.append(kind, o.Args...)
+	}
+	return d, nil
+}
@@ -0,0 +1,144 @@
+// Package errors provides the structured error type shared by the
+// plantuml packages: a wrapped cause, a typed Kind (replacing a set of
+// ad-hoc sentinel errors), a message, and the call stack captured at
+// the point the error was created.
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// pkgPrefix is the function-name prefix of this package, used by
+// captureStack to skip its own frames.
+const pkgPrefix = "github.com/dkostenko/plantuml/errors."
+
+// Kind identifies the category of an Error.
+type Kind int
+
+// Available kinds.
+const (
+	// Internal - internal error.
+	Internal Kind = iota
+
+	// ServerUnavailable - the PlantUML server/backend is unavailable.
+	ServerUnavailable
+
+	// InvalidFormat - the requested diagram output format is invalid.
+	InvalidFormat
+
+	// InvalidDescription - the diagram description is invalid, or
+	// contains a syntax error.
+	InvalidDescription
+
+	// InvalidAddress - the PlantUML server address is invalid.
+	InvalidAddress
+)
+
+// String returns a human-readable description of k.
+func (k Kind) String() string {
+	switch k {
+	case Internal:
+		return "internal error"
+	case ServerUnavailable:
+		return "server is unavailable"
+	case InvalidFormat:
+		return "diagram output format is invalid"
+	case InvalidDescription:
+		return "there is a syntax error in diagram description or the diagram description is empty"
+	case InvalidAddress:
+		return "invalid PlantUML server address"
+	default:
+		return "unknown error"
+	}
+}
+
+// Error wraps a cause with a Kind, a message and the call stack
+// captured when it was created via Wrap.
+type Error struct {
+	Kind  Kind
+	Msg   string
+	Cause error
+
+	stack []uintptr
+}
+
+// Error returns a digested error text.
+func (e *Error) Error() string {
+	msg := e.Msg
+	if msg == "" {
+		msg = e.Kind.String()
+	}
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %s", msg, e.Cause.Error())
+	}
+	return msg
+}
+
+// Unwrap returns the wrapped cause, so the standard library's
+// errors.Is/errors.As work against it too.
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Wrap returns a new *Error of the given kind, wrapping cause (which
+// may be nil), and captures the current call stack.
+func Wrap(cause error, kind Kind, msg string) *Error {
+	return &Error{
+		Kind:  kind,
+		Msg:   msg,
+		Cause: cause,
+		stack: captureStack(),
+	}
+}
+
+// Is reports whether err is an *Error of the given kind.
+func Is(err error, kind Kind) bool {
+	e, ok := err.(*Error)
+	return ok && e.Kind == kind
+}
+
+// StackTrace returns the call stack captured when err was created via
+// Wrap, each frame formatted as "file:line func". It returns nil if
+// err is not an *Error.
+func StackTrace(err error) []string {
+	e, ok := err.(*Error)
+	if !ok || len(e.stack) == 0 {
+		return nil
+	}
+
+	frames := runtime.CallersFrames(e.stack)
+	var trace []string
+	for {
+		frame, more := frames.Next()
+		trace = append(trace, fmt.Sprintf("%s:%d %s", frame.File, frame.Line, frame.Function))
+		if !more {
+			break
+		}
+	}
+	return trace
+}
+
+// captureStack records the call stack at the point Wrap was called,
+// skipping frames that belong to this package itself.
+func captureStack() []uintptr {
+	const maxDepth = 32
+	var pcs [maxDepth]uintptr
+	n := runtime.Callers(1, pcs[:])
+	all := pcs[:n]
+
+	frames := runtime.CallersFrames(all)
+	skip := 0
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, pkgPrefix) {
+			break
+		}
+		skip++
+		if !more {
+			break
+		}
+	}
+	return all[skip:]
+}
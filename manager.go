@@ -1,39 +1,11 @@
 package plantuml
 
 import (
-	"errors"
-	"fmt"
-	"io/ioutil"
-	"net/http"
-	"net/url"
-	"strconv"
 	"strings"
-)
-
-// Error - custom error of this package.
-type Error struct {
-	// Digested error.
-	PackageError error
-
-	// Raw error.
-	RawError error
-}
-
-// Error returns a digested error text.
-func (e *Error) Error() string {
-	if e.PackageError != nil {
-		return e.PackageError.Error()
-	} else if e.RawError != nil {
-		return e.RawError.Error()
-	} else {
-		return ErrInternalError.Error()
-	}
-}
 
-// newError returns an object of custom error of this package.
-func newError(packageError, rawError error) *Error {
-	return &Error{packageError, rawError}
-}
+	"github.com/dkostenko/plantuml/dsl"
+	perrors "github.com/dkostenko/plantuml/errors"
+)
 
 // SyntaxError - description of syntax error.
 type SyntaxError struct {
@@ -47,24 +19,6 @@ type SyntaxError struct {
 	LineWithError string
 }
 
-// Errors of this package.
-var (
-	// ErrInternalError - internal error.
-	ErrInternalError = errors.New("internal error")
-
-	// ErrServerIsUnavailable - 'server is unavailable' error.
-	ErrServerIsUnavailable = errors.New("server is unavailable")
-
-	// ErrInvalidDiagramFormat - 'diagram output format is invalid' error.
-	ErrInvalidDiagramFormat = errors.New("diagram output format is invalid")
-
-	// ErrInvalidDiagramDescription - 'diagram description is invalid' error.
-	ErrInvalidDiagramDescription = errors.New("there is a syntax error in diagram description or the diagram description is empty")
-
-	// ErrInvalidPlantUMLAddress - invalid PlantUML server address.
-	ErrInvalidPlantUMLAddress = errors.New("invalid PlantUML server address")
-)
-
 // DiagramFormat - output format of diagram.
 type DiagramFormat int
 
@@ -80,150 +34,146 @@ const (
 	DiagramFormatSVG
 )
 
-// manager - an implementation of a manager of requests to PlantUML server.
-type manager struct {
-	// PlantUML server address.
-	serverAddr string
+// diagramFormatExt returns the file extension / URL segment that
+// corresponds to format, or a perrors.InvalidFormat error if format
+// is unknown.
+func diagramFormatExt(format DiagramFormat) (string, error) {
+	switch format {
+	case DiagramFormatTXT:
+		return "txt", nil
+	case DiagramFormatPNG:
+		return "png", nil
+	case DiagramFormatSVG:
+		return "svg", nil
+	default:
+		return "", perrors.Wrap(nil, perrors.InvalidFormat, "")
+	}
 }
 
-// Manager of requests to PlantUML server.
-type Manager interface {
+// Backend renders a diagram description over some transport, e.g. an
+// HTTP PlantUML server or a local PlantUML jar.
+type Backend interface {
 	// Render returns diagram file in the specified format.
-	Render(diagramDescription string, format DiagramFormat) ([]byte, *SyntaxError, error)
+	Render(diagramDescription string, format DiagramFormat) ([]byte, []SyntaxError, error)
 }
 
-// NewManager returns client manager object.
-func NewManager(plantUMLServerAddr string) (Manager, error) {
-	// Validate plantUMLServerAddr.
-	_, err := url.ParseRequestURI(plantUMLServerAddr)
-	if err != nil {
-		return nil, newError(ErrInvalidPlantUMLAddress, err)
-	}
+// manager - an implementation of a manager of requests to PlantUML.
+type manager struct {
+	// backend - transport used to render diagrams.
+	backend Backend
 
-	return &manager{serverAddr: plantUMLServerAddr}, nil
+	// cache - optional store of already-rendered diagrams.
+	cache Cache
 }
 
-// Render returns diagram file in the specified format.
-func (m *manager) Render(diagramDescription string, format DiagramFormat) ([]byte, *SyntaxError, error) {
-	// Validate param 'diagramDescription'.
-	diagramDescription = strings.Trim(diagramDescription, " ")
-	if len(diagramDescription) == 0 {
-		return nil, nil, newError(ErrInvalidDiagramDescription, nil)
-	}
+// Manager of requests to PlantUML.
+type Manager interface {
+	// Render returns diagram file in the specified format.
+	Render(diagramDescription string, format DiagramFormat) ([]byte, []SyntaxError, error)
 
-	// Validate param 'format'.
-	var formatURLPart string
-	switch format {
-	case DiagramFormatTXT:
-		formatURLPart = "txt"
-	case DiagramFormatPNG:
-		formatURLPart = "png"
-	case DiagramFormatSVG:
-		formatURLPart = "svg"
-	default:
-		return nil, nil, newError(ErrInvalidDiagramFormat, nil)
-	}
+	// RenderDiagram returns diagram file in the specified format for
+	// a diagram built with the dsl package.
+	RenderDiagram(d *dsl.Diagram, format DiagramFormat) ([]byte, []SyntaxError, error)
 
-	// 1. Get rendered diagram ID.
-	link := fmt.Sprintf("%s/form", m.serverAddr)
-	imgID, err := getDiagramID(link, diagramDescription)
-	if err != nil {
-		return nil, nil, err
+	// CachedDiagram returns a previously rendered diagram by its
+	// cache key (as returned by CacheKey), if a cache was configured
+	// via WithCache and the entry is still present.
+	CachedDiagram(key string) ([]byte, bool)
+
+	// RenderAll splits diagramDescription on "@startuml"/"@enduml"
+	// boundaries and renders each diagram block in order.
+	RenderAll(diagramDescription string, format DiagramFormat) ([]RenderedDiagram, error)
+}
+
+// Option configures a Manager created via NewManager.
+type Option func(*manager)
+
+// WithBackend selects the transport used to render diagrams. It
+// overrides the default HTTP backend built from plantUMLServerAddr.
+func WithBackend(backend Backend) Option {
+	return func(m *manager) {
+		m.backend = backend
 	}
+}
 
-	// 2. Get the diagram as TXT to check an error existence.
-	link = fmt.Sprintf("%s/txt/%s", m.serverAddr, imgID)
-	diagramFile, hasSyntaxError, err := downloadDiagram(link)
-	if err != nil {
-		return nil, nil, err
+// WithCache enables a rendered-diagram cache, checked before invoking
+// the backend and populated after a successful render.
+func WithCache(cache Cache) Option {
+	return func(m *manager) {
+		m.cache = cache
 	}
+}
 
-	// 3. Check the error if needed.
-	if hasSyntaxError {
-		syntaxError := getErrorLineNumber(string(diagramFile))
-		if syntaxError != nil {
-			return nil, syntaxError, newError(ErrInvalidDiagramDescription, nil)
+// NewManager returns client manager object. By default it renders
+// through an HTTP PlantUML server at plantUMLServerAddr; pass
+// WithBackend to render through a different transport instead, in
+// which case plantUMLServerAddr may be left empty.
+func NewManager(plantUMLServerAddr string, opts ...Option) (Manager, error) {
+	m := &manager{}
+
+	if plantUMLServerAddr != "" {
+		backend, err := newHTTPBackend(plantUMLServerAddr)
+		if err != nil {
+			return nil, err
 		}
+		m.backend = backend
 	}
 
-	// 4. Render the diagram in a requred format.
-	if format == DiagramFormatTXT {
-		return diagramFile, nil, nil
+	for _, opt := range opts {
+		opt(m)
 	}
-	link = fmt.Sprintf("%s/%s/%s", m.serverAddr, formatURLPart, imgID)
-	diagramFile, _, err = downloadDiagram(link)
-	if err != nil {
-		return nil, nil, err
+
+	if m.backend == nil {
+		return nil, perrors.Wrap(nil, perrors.InvalidAddress, "")
 	}
 
-	return diagramFile, nil, nil
+	return m, nil
 }
 
-// getDiagramID returns ID of the rendered diagram.
-func getDiagramID(link, diagramDescription string) (string, error) {
-	resp, err := http.PostForm(link, url.Values{"text": {diagramDescription}})
-	if err != nil {
-		return "", newError(ErrInternalError, err)
+// Render returns diagram file in the specified format.
+func (m *manager) Render(diagramDescription string, format DiagramFormat) ([]byte, []SyntaxError, error) {
+	// Validate param 'diagramDescription'.
+	diagramDescription = strings.Trim(diagramDescription, " ")
+	if len(diagramDescription) == 0 {
+		return nil, nil, perrors.Wrap(nil, perrors.InvalidDescription, "diagram description is empty")
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 && resp.StatusCode != 400 {
-		return "", newError(ErrServerIsUnavailable, err)
+	// Validate param 'format'.
+	if _, err := diagramFormatExt(format); err != nil {
+		return nil, nil, err
 	}
 
-	urlParts := strings.Split(resp.Request.URL.String(), "/")
-	imgID := urlParts[len(urlParts)-1]
-	return imgID, nil
-}
-
-// downloadDiagram returns diagram and 'has syntax error' flag.
-func downloadDiagram(link string) ([]byte, bool, error) {
-	resp, err := http.Get(link)
-	if err != nil {
-		return nil, false, newError(ErrInternalError, err)
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != 200 && resp.StatusCode != 400 {
-		return nil, false, newError(ErrServerIsUnavailable, nil)
+	var key string
+	if m.cache != nil {
+		key, _ = CacheKey(diagramDescription, format)
+		if data, ok := m.cache.Get(key); ok {
+			return data, nil, nil
+		}
 	}
-	diagramFile, err := ioutil.ReadAll(resp.Body)
+
+	diagramFile, syntaxErrors, err := m.backend.Render(diagramDescription, format)
 	if err != nil {
-		return nil, false, newError(ErrInternalError, err)
+		return nil, syntaxErrors, err
 	}
-	if resp.StatusCode == 400 {
-		return diagramFile, true, nil
-	}
-	return diagramFile, false, nil
-}
 
-// getErrorLineNumber returns an object which describes a syntax error.
-//
-// It's consider, that an error exists when the diagram (in TXT format) contains
-// a substring "[From string (line " in the first line.
-func getErrorLineNumber(diagramAsTXT string) *SyntaxError {
-	lines := strings.Split(diagramAsTXT, "\n")
-	firstLine := lines[0]
-	lastLine := lines[len(lines)-1]
-
-	if ok := strings.HasPrefix(firstLine, "[From string (line "); !ok {
-		return nil
+	if m.cache != nil {
+		m.cache.Put(key, diagramFile)
 	}
 
-	lastLine = strings.TrimLeft(lastLine, " Syntax error: ")
-	firstLine = strings.TrimLeft(firstLine, "[From string (line ")
-	firstLine = strings.TrimRight(firstLine, ") ]")
-	lineNumber, err := strconv.Atoi(firstLine)
-	if err != nil {
-		return &SyntaxError{
-			LineNumber:    0,
-			LineWithError: lastLine,
-			RawError:      diagramAsTXT,
-		}
-	}
+	return diagramFile, nil, nil
+}
 
-	return &SyntaxError{
-		LineNumber:    int64(lineNumber),
-		LineWithError: lastLine,
-		RawError:      diagramAsTXT,
+// CachedDiagram returns a previously rendered diagram by its cache
+// key, if a cache was configured and the entry is still present.
+func (m *manager) CachedDiagram(key string) ([]byte, bool) {
+	if m.cache == nil {
+		return nil, false
 	}
+	return m.cache.Get(key)
+}
+
+// RenderDiagram returns diagram file in the specified format for a
+// diagram built with the dsl package.
+func (m *manager) RenderDiagram(d *dsl.Diagram, format DiagramFormat) ([]byte, []SyntaxError, error) {
+	return m.Render(d.String(), format)
 }
@@ -0,0 +1,33 @@
+package plantuml
+
+import (
+	"bytes"
+	"compress/flate"
+	"io/ioutil"
+)
+
+// EncodePermalink encodes a diagram description with PlantUML's
+// DEFLATE+base64 text-encoding scheme, suitable for embedding in a
+// permalink URL.
+func EncodePermalink(diagramDescription string) (string, error) {
+	return encodePlantUML(diagramDescription)
+}
+
+// DecodePermalink reverses EncodePermalink, returning the original
+// diagram description.
+func DecodePermalink(encoded string) (string, error) {
+	data, err := decodePlantUMLBytes(encoded)
+	if err != nil {
+		return "", err
+	}
+
+	r := flate.NewReader(bytes.NewReader(data))
+	defer r.Close()
+
+	desc, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	return string(desc), nil
+}
@@ -0,0 +1,93 @@
+package plantuml
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ParseSyntaxErrors parses a diagram rendered as TXT that contains one
+// or more syntax errors and returns every error found, in the order
+// it appears. It understands the classic single-error
+// "[From string (line N) ]" format and the "ERROR\n<line>\n<msg>"
+// format PlantUML also emits, including batches of either. In both
+// formats LineWithError is set to the message PlantUML printed for
+// the error (<msg>, or the text after "Syntax error: "), not to a
+// line of the diagram source.
+func ParseSyntaxErrors(diagramAsTXT string) []SyntaxError {
+	if errs := parseBracketSyntaxErrors(diagramAsTXT); len(errs) > 0 {
+		return errs
+	}
+	return parsePlainSyntaxErrors(diagramAsTXT)
+}
+
+// parseBracketSyntaxErrors parses one or more
+// "[From string (line N) ]\n...\nSyntax error: <msg>" blocks.
+func parseBracketSyntaxErrors(diagramAsTXT string) []SyntaxError {
+	const blockPrefix = "[From string (line "
+
+	var errs []SyntaxError
+	var cur *SyntaxError
+	var tail string
+
+	flush := func() {
+		if cur == nil {
+			return
+		}
+		cur.LineWithError = strings.TrimPrefix(tail, "Syntax error: ")
+		errs = append(errs, *cur)
+	}
+
+	for _, line := range strings.Split(diagramAsTXT, "\n") {
+		if strings.HasPrefix(line, blockPrefix) {
+			flush()
+
+			rest := strings.TrimPrefix(line, blockPrefix)
+			end := 0
+			for end < len(rest) && rest[end] >= '0' && rest[end] <= '9' {
+				end++
+			}
+
+			lineNumber := int64(0)
+			if n, err := strconv.Atoi(rest[:end]); err == nil {
+				lineNumber = int64(n)
+			}
+
+			cur = &SyntaxError{LineNumber: lineNumber, RawError: diagramAsTXT}
+			tail = ""
+			continue
+		}
+
+		if cur != nil && strings.TrimSpace(line) != "" {
+			tail = strings.TrimRight(line, "\r")
+		}
+	}
+	flush()
+
+	return errs
+}
+
+// parsePlainSyntaxErrors parses one or more "ERROR\n<line>\n<msg>"
+// blocks.
+func parsePlainSyntaxErrors(diagramAsTXT string) []SyntaxError {
+	lines := strings.Split(diagramAsTXT, "\n")
+
+	var errs []SyntaxError
+	for i, line := range lines {
+		if strings.TrimRight(line, "\r") != "ERROR" || i+2 >= len(lines) {
+			continue
+		}
+
+		lineNumber, err := strconv.Atoi(strings.TrimSpace(lines[i+1]))
+		if err != nil {
+			continue
+		}
+
+		errs = append(errs, SyntaxError{
+			LineNumber:    int64(lineNumber),
+			LineWithError: strings.TrimRight(lines[i+2], "\r"),
+			RawError:      diagramAsTXT,
+		})
+	}
+
+	return errs
+}